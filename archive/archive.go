@@ -0,0 +1,221 @@
+// Package archive creates, lists, and extracts tar and tar.gz archives.
+package archive
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Entry describes a single archived file, as returned by List.
+type Entry struct {
+	Name  string
+	Size  int64
+	Mode  int64
+	IsDir bool
+}
+
+// Tar writes source (a file or directory) into the archive at target.
+// When target ends in .tar.gz or .tgz, the tar stream is gzipped.
+func Tar(source, target string) error {
+	out, err := os.Create(target)
+	if err != nil {
+		return fmt.Errorf("archive: create %q: %w", target, err)
+	}
+	defer out.Close()
+
+	var w io.Writer = out
+	if isGzip(target) {
+		gz := gzip.NewWriter(out)
+		defer gz.Close()
+		w = gz
+	}
+
+	tw := tar.NewWriter(w)
+	defer tw.Close()
+
+	root := filepath.Clean(source)
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		// Entry names are relative to root itself, not its parent, so
+		// extracting the archive reproduces root's contents directly
+		// rather than nesting them under root's own basename.
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			if info.IsDir() {
+				return nil
+			}
+			// source is itself a single file: there's no parent
+			// directory entry to omit, so archive it under its own name.
+			rel = filepath.Base(root)
+		}
+		rel = filepath.ToSlash(rel)
+
+		var link string
+		if info.Mode()&os.ModeSymlink != 0 {
+			link, err = os.Readlink(path)
+			if err != nil {
+				return err
+			}
+		}
+
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		header.Name = rel
+		if info.IsDir() {
+			header.Name += "/"
+		}
+
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		_, err = io.Copy(tw, f)
+		return err
+	})
+}
+
+// Untar extracts tarball into target, creating it if necessary. Header
+// entries whose cleaned path would escape target are rejected, guarding
+// against zip-slip.
+func Untar(tarball, target string) error {
+	f, err := os.Open(tarball)
+	if err != nil {
+		return fmt.Errorf("archive: open %q: %w", tarball, err)
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if isGzip(tarball) {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return fmt.Errorf("archive: gzip reader: %w", err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("archive: read header: %w", err)
+		}
+
+		dest, err := safeJoin(target, header.Name)
+		if err != nil {
+			return err
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(dest, os.FileMode(header.Mode)); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+				return err
+			}
+			out, err := os.OpenFile(dest, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, os.FileMode(header.Mode))
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(out, tr); err != nil {
+				out.Close()
+				return err
+			}
+			out.Close()
+		case tar.TypeSymlink:
+			if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+				return err
+			}
+			_ = os.Remove(dest)
+			if err := os.Symlink(header.Linkname, dest); err != nil {
+				return err
+			}
+			continue
+		default:
+			continue
+		}
+
+		if err := os.Chtimes(dest, header.ModTime, header.ModTime); err != nil {
+			return err
+		}
+		_ = os.Chown(dest, header.Uid, header.Gid)
+	}
+}
+
+// List previews the entries of tarball without extracting them.
+func List(tarball string) ([]Entry, error) {
+	f, err := os.Open(tarball)
+	if err != nil {
+		return nil, fmt.Errorf("archive: open %q: %w", tarball, err)
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if isGzip(tarball) {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, fmt.Errorf("archive: gzip reader: %w", err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	var entries []Entry
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("archive: read header: %w", err)
+		}
+		entries = append(entries, Entry{
+			Name:  header.Name,
+			Size:  header.Size,
+			Mode:  header.Mode,
+			IsDir: header.Typeflag == tar.TypeDir,
+		})
+	}
+	return entries, nil
+}
+
+func isGzip(name string) bool {
+	return strings.HasSuffix(name, ".tar.gz") || strings.HasSuffix(name, ".tgz")
+}
+
+// safeJoin joins target and name, rejecting any result that escapes target.
+func safeJoin(target, name string) (string, error) {
+	dest := filepath.Join(target, name)
+	if dest != target && !strings.HasPrefix(dest, target+string(os.PathSeparator)) {
+		return "", fmt.Errorf("archive: illegal file path %q", name)
+	}
+	return dest, nil
+}
@@ -0,0 +1,151 @@
+package archive
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+func TestTarUntarRoundTripsDirectory(t *testing.T) {
+	src := t.TempDir()
+	os.MkdirAll(filepath.Join(src, "nested"), 0o755)
+	os.WriteFile(filepath.Join(src, "a.txt"), []byte("a"), 0o644)
+	os.WriteFile(filepath.Join(src, "nested", "b.txt"), []byte("b"), 0o644)
+
+	tarball := filepath.Join(t.TempDir(), "out.tar")
+	if err := Tar(src, tarball); err != nil {
+		t.Fatalf("Tar: %v", err)
+	}
+
+	dest := t.TempDir()
+	if err := Untar(tarball, dest); err != nil {
+		t.Fatalf("Untar: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dest, "a.txt"))
+	if err != nil || string(data) != "a" {
+		t.Fatalf("a.txt = %q, %v; want %q", data, err, "a")
+	}
+	data, err = os.ReadFile(filepath.Join(dest, "nested", "b.txt"))
+	if err != nil || string(data) != "b" {
+		t.Fatalf("nested/b.txt = %q, %v; want %q", data, err, "b")
+	}
+
+	// Regression: entries must not be nested under the source directory's
+	// own basename (the "baseDir prefix" pitfall).
+	if _, err := os.Stat(filepath.Join(dest, filepath.Base(src), "a.txt")); err == nil {
+		t.Fatalf("archive nested contents under source's own basename %q", filepath.Base(src))
+	}
+}
+
+func TestTarSingleFile(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "file.txt")
+	if err := os.WriteFile(src, []byte("contents"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tarball := filepath.Join(t.TempDir(), "out.tar")
+	if err := Tar(src, tarball); err != nil {
+		t.Fatalf("Tar: %v", err)
+	}
+
+	entries, err := List(tarball)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("List returned %d entries, want 1 (archiving a single file must not produce an empty archive)", len(entries))
+	}
+	if entries[0].Name != "file.txt" {
+		t.Fatalf("entry name = %q, want %q", entries[0].Name, "file.txt")
+	}
+
+	dest := t.TempDir()
+	if err := Untar(tarball, dest); err != nil {
+		t.Fatalf("Untar: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dest, "file.txt"))
+	if err != nil || string(data) != "contents" {
+		t.Fatalf("extracted file = %q, %v; want %q", data, err, "contents")
+	}
+}
+
+func TestTarGzRoundTrip(t *testing.T) {
+	src := t.TempDir()
+	os.WriteFile(filepath.Join(src, "a.txt"), []byte("a"), 0o644)
+
+	tarball := filepath.Join(t.TempDir(), "out.tar.gz")
+	if err := Tar(src, tarball); err != nil {
+		t.Fatalf("Tar: %v", err)
+	}
+
+	dest := t.TempDir()
+	if err := Untar(tarball, dest); err != nil {
+		t.Fatalf("Untar: %v", err)
+	}
+	if _, err := os.ReadFile(filepath.Join(dest, "a.txt")); err != nil {
+		t.Fatalf("a.txt missing after gzip round trip: %v", err)
+	}
+}
+
+func TestUntarPreservesSymlinks(t *testing.T) {
+	src := t.TempDir()
+	os.WriteFile(filepath.Join(src, "target.txt"), []byte("target"), 0o644)
+	if err := os.Symlink("target.txt", filepath.Join(src, "link.txt")); err != nil {
+		t.Fatal(err)
+	}
+
+	tarball := filepath.Join(t.TempDir(), "out.tar")
+	if err := Tar(src, tarball); err != nil {
+		t.Fatalf("Tar: %v", err)
+	}
+
+	dest := t.TempDir()
+	if err := Untar(tarball, dest); err != nil {
+		t.Fatalf("Untar: %v", err)
+	}
+
+	linkDest, err := os.Readlink(filepath.Join(dest, "link.txt"))
+	if err != nil {
+		t.Fatalf("link.txt was not restored as a symlink: %v", err)
+	}
+	if linkDest != "target.txt" {
+		t.Fatalf("symlink target = %q, want %q", linkDest, "target.txt")
+	}
+}
+
+func TestUntarRejectsZipSlip(t *testing.T) {
+	dest := t.TempDir()
+	if _, err := safeJoin(dest, "../../etc/passwd"); err == nil {
+		t.Fatal("expected safeJoin to reject a path escaping the target directory")
+	}
+	if dest2, err := safeJoin(dest, "nested/file.txt"); err != nil || dest2 != filepath.Join(dest, "nested/file.txt") {
+		t.Fatalf("safeJoin(normal path) = %q, %v", dest2, err)
+	}
+}
+
+func TestList(t *testing.T) {
+	src := t.TempDir()
+	os.WriteFile(filepath.Join(src, "a.txt"), []byte("aa"), 0o644)
+	os.MkdirAll(filepath.Join(src, "dir"), 0o755)
+
+	tarball := filepath.Join(t.TempDir(), "out.tar")
+	if err := Tar(src, tarball); err != nil {
+		t.Fatalf("Tar: %v", err)
+	}
+
+	entries, err := List(tarball)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name)
+	}
+	sort.Strings(names)
+	if len(names) != 2 || names[0] != "a.txt" || names[1] != "dir/" {
+		t.Fatalf("List = %v, want [a.txt dir/]", names)
+	}
+}
@@ -0,0 +1,184 @@
+// Package gitinfo shells out to git to decorate file listings with status
+// glyphs and render diffs, caching results per repository until they go
+// stale.
+package gitinfo
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DiffLine is a single line of a rendered diff, tagged with its kind so the
+// caller can colorize it.
+type DiffLine struct {
+	Text string
+	Kind LineKind
+}
+
+// LineKind classifies a DiffLine for colorization.
+type LineKind int
+
+const (
+	Context LineKind = iota
+	Addition
+	Deletion
+	Hunk
+)
+
+type cacheEntry struct {
+	status  map[string]string
+	modTime time.Time
+}
+
+var (
+	mu    sync.Mutex
+	cache = map[string]cacheEntry{}
+)
+
+// Status returns a map from slash-separated path, relative to the repository
+// root (not to dir), to its `git status --porcelain=v1` code (e.g. "M", "A",
+// "D", "??"), for the repository containing dir. Results are cached per
+// repository root and invalidated when the mtime of .git/index advances
+// (the root directory's mtime is used if the index cannot be read); a
+// working tree edit alone does not invalidate the cache.
+func Status(dir string) (map[string]string, error) {
+	root, err := repoRoot(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	mt := repoMTime(root)
+
+	mu.Lock()
+	if entry, ok := cache[root]; ok && !mt.After(entry.modTime) {
+		mu.Unlock()
+		return entry.status, nil
+	}
+	mu.Unlock()
+
+	status, err := runStatus(root)
+	if err != nil {
+		return nil, err
+	}
+
+	mu.Lock()
+	cache[root] = cacheEntry{status: status, modTime: mt}
+	mu.Unlock()
+
+	return status, nil
+}
+
+func runStatus(root string) (map[string]string, error) {
+	cmd := exec.Command("git", "status", "--porcelain=v1", "-z")
+	cmd.Dir = root
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("gitinfo: git status: %w", err)
+	}
+
+	status := make(map[string]string)
+	entries := strings.Split(strings.TrimRight(string(out), "\x00"), "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 4 {
+			continue
+		}
+		code := strings.TrimSpace(entry[:2])
+		name := entry[3:]
+		// With -z, a rename or copy entry holds the new name and is followed
+		// by a separate field holding the old name; key by the new name and
+		// skip the old one.
+		if strings.ContainsAny(entry[:2], "RC") {
+			i++
+		}
+		status[filepath.ToSlash(name)] = code
+	}
+	return status, nil
+}
+
+// Diff renders the diff for path (absolute, or relative to the current
+// working directory), optionally against the index (cached=true) instead
+// of the working tree.
+func Diff(path string, cached bool) ([]DiffLine, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("gitinfo: resolve %q: %w", path, err)
+	}
+	path = abs
+
+	dir := filepath.Dir(path)
+	root, err := repoRoot(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return nil, fmt.Errorf("gitinfo: relativize %q: %w", path, err)
+	}
+
+	args := []string{"diff", "--no-color"}
+	if cached {
+		args = append(args, "--cached")
+	}
+	args = append(args, "--", rel)
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = root
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("gitinfo: git diff: %w", err)
+	}
+
+	var lines []DiffLine
+	for _, text := range strings.Split(string(out), "\n") {
+		lines = append(lines, DiffLine{Text: text, Kind: classify(text)})
+	}
+	return lines, nil
+}
+
+func classify(line string) LineKind {
+	switch {
+	case strings.HasPrefix(line, "@@"):
+		return Hunk
+	case strings.HasPrefix(line, "+") && !strings.HasPrefix(line, "+++"):
+		return Addition
+	case strings.HasPrefix(line, "-") && !strings.HasPrefix(line, "---"):
+		return Deletion
+	default:
+		return Context
+	}
+}
+
+// Root returns the top-level working tree directory for the repository
+// containing dir, so callers can rewrite Status's repo-root-relative keys
+// into paths relative to whatever directory they're rendering.
+func Root(dir string) (string, error) {
+	return repoRoot(dir)
+}
+
+func repoRoot(dir string) (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("gitinfo: not a git repository: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// repoMTime approximates the working tree's freshness by the mtime of the
+// index file, falling back to the repo root itself if it can't be read.
+func repoMTime(root string) time.Time {
+	for _, candidate := range []string{filepath.Join(root, ".git", "index"), root} {
+		if fi, err := os.Stat(candidate); err == nil {
+			return fi.ModTime()
+		}
+	}
+	return time.Time{}
+}
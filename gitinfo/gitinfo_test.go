@@ -0,0 +1,113 @@
+package gitinfo
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func initRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	run := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	run("init", "-q")
+	run("config", "user.email", "test@example.com")
+	run("config", "user.name", "Test")
+
+	os.MkdirAll(filepath.Join(dir, "src"), 0o755)
+	os.WriteFile(filepath.Join(dir, "src", "main.go"), []byte("package main\n"), 0o644)
+	run("add", ".")
+	run("commit", "-q", "-m", "initial")
+
+	return dir
+}
+
+func TestStatusKeysAreRepoRootRelative(t *testing.T) {
+	dir := initRepo(t)
+	os.WriteFile(filepath.Join(dir, "src", "main.go"), []byte("package main\n\nfunc main() {}\n"), 0o644)
+
+	status, err := Status(filepath.Join(dir, "src"))
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+
+	code, ok := status["src/main.go"]
+	if !ok {
+		t.Fatalf("Status(%q) = %+v, want an entry for %q", dir, status, "src/main.go")
+	}
+	if code != "M" {
+		t.Fatalf("status code for src/main.go = %q, want %q", code, "M")
+	}
+}
+
+func TestStatusCachesUntilIndexChanges(t *testing.T) {
+	dir := initRepo(t)
+
+	first, err := Status(dir)
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if len(first) != 0 {
+		t.Fatalf("Status on a clean repo = %+v, want empty", first)
+	}
+
+	os.WriteFile(filepath.Join(dir, "src", "main.go"), []byte("changed\n"), 0o644)
+
+	// Touch the index so the cache invalidates (mirrors what `git add`
+	// would do; Status itself doesn't run `git add`).
+	cmd := exec.Command("git", "add", ".")
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git add: %v\n%s", err, out)
+	}
+
+	second, err := Status(dir)
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if len(second) == 0 {
+		t.Fatal("Status did not pick up the change after the index was updated")
+	}
+}
+
+func TestDiffRendersAdditionsAndDeletions(t *testing.T) {
+	dir := initRepo(t)
+	path := filepath.Join(dir, "src", "main.go")
+	os.WriteFile(path, []byte("package main\n\nfunc main() {}\n"), 0o644)
+
+	lines, err := Diff(path, false)
+	if err != nil {
+		t.Fatalf("Diff: %v", err)
+	}
+
+	var sawAddition, sawHunk bool
+	for _, line := range lines {
+		switch line.Kind {
+		case Addition:
+			sawAddition = true
+		case Hunk:
+			sawHunk = true
+		}
+	}
+	if !sawAddition || !sawHunk {
+		t.Fatalf("Diff lines = %+v, want at least one Addition and one Hunk", lines)
+	}
+}
+
+func TestRoot(t *testing.T) {
+	dir := initRepo(t)
+	root, err := Root(filepath.Join(dir, "src"))
+	if err != nil {
+		t.Fatalf("Root: %v", err)
+	}
+	if root != dir {
+		t.Fatalf("Root = %q, want %q", root, dir)
+	}
+}
@@ -6,12 +6,19 @@ import (
 	"os/exec"
 	"path/filepath"
 	"sort"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 	"golang.org/x/term"
+
+	"linux-filemanager/archive"
+	"linux-filemanager/gitinfo"
+	"linux-filemanager/snapshot"
+	"linux-filemanager/trash"
+	"linux-filemanager/watcher"
 )
 
 type FileManager struct {
@@ -22,24 +29,90 @@ type FileManager struct {
 	items      []string
 	oldState   *term.State // To store original terminal state
 	flex       *tview.Flex
+	pages      *tview.Pages
 	vimView    *tview.TextView
 	vimRunning bool
+
+	trash      *trash.Store
+	snapshots  *snapshot.Store
+	watcher    *watcher.Watcher
+	diffCached bool
 }
 
 func NewFileManager(path string) *FileManager {
+	trashStore, err := trash.NewStore()
+	if err != nil {
+		panic(err)
+	}
+
+	snapshotPath, err := snapshot.DefaultPath()
+	if err != nil {
+		panic(err)
+	}
+	snapshotStore, err := snapshot.Open(snapshotPath)
+	if err != nil {
+		panic(err)
+	}
+
 	fm := &FileManager{
-		app:     tview.NewApplication(),
-		list:    tview.NewList(),
-		details: tview.NewTextView(),
-		path:    path,
-		flex:    tview.NewFlex(),
+		app:       tview.NewApplication(),
+		list:      tview.NewList(),
+		details:   tview.NewTextView(),
+		path:      path,
+		flex:      tview.NewFlex(),
+		pages:     tview.NewPages(),
+		trash:     trashStore,
+		snapshots: snapshotStore,
+	}
+
+	w, err := watcher.New(func() {
+		fm.app.QueueUpdateDraw(fm.loadItems)
+	})
+	if err != nil {
+		panic(err)
 	}
+	fm.watcher = w
 
+	fm.details.SetDynamicColors(true)
 	fm.loadItems()
+
+	if err := fm.watcher.Watch(fm.path); err != nil {
+		panic(err)
+	}
+
 	return fm
 }
 
+// Trash moves path into the trash can, returning the trashed item's id.
+func (fm *FileManager) Trash(path string) (string, error) {
+	item, err := fm.trash.Trash(path)
+	if err != nil {
+		return "", err
+	}
+	return item.ID, nil
+}
+
+// Restore moves a previously trashed item back to its original location.
+func (fm *FileManager) Restore(id string) (string, error) {
+	return fm.trash.Restore(id)
+}
+
+// List returns the items currently sitting in the trash.
+func (fm *FileManager) List() ([]trash.Item, error) {
+	return fm.trash.List()
+}
+
+// Empty permanently deletes everything in the trash.
+func (fm *FileManager) Empty() error {
+	return fm.trash.Empty()
+}
+
 func (fm *FileManager) loadItems() {
+	var selected string
+	if idx := fm.list.GetCurrentItem(); idx >= 0 && idx < len(fm.items) {
+		selected = fm.items[idx]
+	}
+
 	fm.list.Clear()
 	entries, err := os.ReadDir(fm.path)
 	if err != nil {
@@ -59,6 +132,11 @@ func (fm *FileManager) loadItems() {
 	sort.Strings(files)
 
 	fm.items = append(dirs, files...)
+
+	gitStatus, _ := gitinfo.Status(fm.path)
+	repoRoot, repoErr := gitinfo.Root(fm.path)
+	absPath, absErr := filepath.Abs(fm.path)
+
 	for _, item := range fm.items {
 		color := tcell.ColorWhite
 		info, err := os.Stat(filepath.Join(fm.path, item))
@@ -70,7 +148,27 @@ func (fm *FileManager) loadItems() {
 		} else if (info.Mode() & os.ModeSymlink) != 0 {
 			color = tcell.ColorFuchsia
 		}
-		fm.list.AddItem(item, "", 0, nil).SetMainTextColor(color)
+
+		secondary := ""
+		if repoErr == nil && absErr == nil {
+			// Status is keyed relative to the repo root, not fm.path, so
+			// rewrite item's key before looking it up.
+			if rel, err := filepath.Rel(repoRoot, filepath.Join(absPath, item)); err == nil {
+				if code, ok := gitStatus[filepath.ToSlash(rel)]; ok {
+					secondary = code
+				}
+			}
+		}
+		fm.list.AddItem(item, secondary, 0, nil).SetMainTextColor(color)
+	}
+
+	if selected != "" {
+		for i, item := range fm.items {
+			if item == selected {
+				fm.list.SetCurrentItem(i)
+				break
+			}
+		}
 	}
 
 	fm.updateDetails()
@@ -86,6 +184,9 @@ func (fm *FileManager) navigate(item string) {
 	if info.IsDir() {
 		fm.path = newPath
 		fm.loadItems()
+		if err := fm.watcher.Watch(fm.path); err != nil {
+			fm.details.SetText(fmt.Sprintf("Error watching %q: %v", fm.path, err))
+		}
 	} else {
 		fm.openInVim(newPath)
 	}
@@ -172,9 +273,27 @@ func (fm *FileManager) updateDetails() {
 		group,
 		modTime,
 	)
+
+	if !info.IsDir() && isArchive(fullPath) {
+		entries, err := archive.List(fullPath)
+		if err != nil {
+			details += fmt.Sprintf("\nError listing archive: %v\n", err)
+		} else {
+			details += "\nContents:\n"
+			for _, entry := range entries {
+				details += fmt.Sprintf("  %s (%d bytes)\n", entry.Name, entry.Size)
+			}
+		}
+	}
+
 	fm.details.SetText(details)
 }
 
+// isArchive reports whether path has a recognized tar/tar.gz extension.
+func isArchive(path string) bool {
+	return strings.HasSuffix(path, ".tar") || strings.HasSuffix(path, ".tar.gz") || strings.HasSuffix(path, ".tgz")
+}
+
 func (fm *FileManager) deleteSelectedItem() {
 	selectedIndex := fm.list.GetCurrentItem()
 	if selectedIndex < 0 || selectedIndex >= len(fm.items) {
@@ -184,15 +303,238 @@ func (fm *FileManager) deleteSelectedItem() {
 	selectedItem := fm.items[selectedIndex]
 	fullPath := filepath.Join(fm.path, selectedItem)
 
-	err := os.RemoveAll(fullPath) // Remove files or directories
+	if _, err := fm.Trash(fullPath); err != nil {
+		fm.details.SetText(fmt.Sprintf("Error trashing file: %v", err))
+		return
+	}
+
+	fm.loadItems()
+}
+
+// undoTrash restores the most recently trashed item to its original path.
+func (fm *FileManager) undoTrash() {
+	item, err := fm.trash.Undo()
+	if err != nil {
+		fm.details.SetText(fmt.Sprintf("Nothing to undo: %v", err))
+		return
+	}
+	fm.loadItems()
+	fm.details.SetText(fmt.Sprintf("Restored %s", item.OriginalPath))
+}
+
+// showTrashModal displays a list of trashed items with restore/purge actions,
+// bound to 'r' and 'x' respectively, and 'q'/Escape to dismiss.
+func (fm *FileManager) showTrashModal() {
+	items, err := fm.trash.List()
+	if err != nil {
+		fm.details.SetText(fmt.Sprintf("Error listing trash: %v", err))
+		return
+	}
+
+	trashList := tview.NewList()
+	trashList.SetBorder(true).SetTitle("Trash (r: restore, x: purge, q: close)")
+	for _, item := range items {
+		trashList.AddItem(item.OriginalPath, item.DeletedAt.Format(time.RFC1123), 0, nil)
+	}
+
+	trashList.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		index := trashList.GetCurrentItem()
+		if index < 0 || index >= len(items) {
+			if event.Rune() == 'q' || event.Key() == tcell.KeyEscape {
+				fm.pages.RemovePage("trash")
+				fm.loadItems()
+			}
+			return nil
+		}
+
+		switch event.Rune() {
+		case 'r':
+			if _, err := fm.trash.Restore(items[index].ID); err != nil {
+				fm.promptRestoreName(items[index].ID)
+			}
+			fm.pages.RemovePage("trash")
+			fm.loadItems()
+			return nil
+		case 'x':
+			_ = fm.trash.Purge(items[index].ID)
+			fm.pages.RemovePage("trash")
+			fm.showTrashModal()
+			return nil
+		case 'q':
+			fm.pages.RemovePage("trash")
+			fm.loadItems()
+			return nil
+		}
+
+		if event.Key() == tcell.KeyEscape {
+			fm.pages.RemovePage("trash")
+			fm.loadItems()
+			return nil
+		}
+		return event
+	})
+
+	fm.pages.AddPage("trash", trashList, true, true)
+}
+
+// promptSnapshotName asks for a snapshot name and invokes onName once
+// the user confirms with Enter.
+func (fm *FileManager) promptSnapshotName(label string, onName func(name string)) {
+	input := tview.NewInputField().SetLabel(label)
+
+	input.SetDoneFunc(func(key tcell.Key) {
+		name := input.GetText()
+		fm.pages.RemovePage("snapshot-prompt")
+		if key == tcell.KeyEnter && name != "" {
+			onName(name)
+		}
+	})
+
+	fm.pages.AddPage("snapshot-prompt", input, true, true)
+}
+
+// takeSnapshot hashes fm.path and stores it under the given name.
+func (fm *FileManager) takeSnapshot() {
+	fm.promptSnapshotName("Snapshot name: ", func(name string) {
+		if err := fm.snapshots.Take(name, fm.path); err != nil {
+			fm.details.SetText(fmt.Sprintf("Error taking snapshot: %v", err))
+			return
+		}
+		fm.details.SetText(fmt.Sprintf("Snapshot %q saved", name))
+	})
+}
+
+// diffSnapshot compares fm.path against a previously stored snapshot and
+// renders the added/modified/deleted entries in the details pane.
+func (fm *FileManager) diffSnapshot() {
+	fm.promptSnapshotName("Diff against snapshot: ", func(name string) {
+		changes, err := fm.snapshots.Diff(name, fm.path)
+		if err != nil {
+			fm.details.SetText(fmt.Sprintf("Error diffing snapshot: %v", err))
+			return
+		}
+
+		var out strings.Builder
+		fmt.Fprintf(&out, "Diff against %q:\n", name)
+		for _, change := range changes {
+			switch change.Kind {
+			case snapshot.Added:
+				fmt.Fprintf(&out, "[green]+ %s[-]\n", change.Path)
+			case snapshot.Modified:
+				fmt.Fprintf(&out, "[yellow]~ %s[-]\n", change.Path)
+			case snapshot.Deleted:
+				fmt.Fprintf(&out, "[red]- %s[-]\n", change.Path)
+			}
+		}
+		fm.details.SetText(out.String())
+	})
+}
+
+// toggleDiffCached flips between diffing the working tree and the index,
+// then re-renders the diff for the current selection. Bound to 'c' rather
+// than 'D', since 'D' is already taken by diffSnapshot.
+func (fm *FileManager) toggleDiffCached() {
+	fm.diffCached = !fm.diffCached
+	fm.showGitDiff()
+}
+
+// showGitDiff replaces the details pane with the colorized git diff for the
+// selected file, against the index when fm.diffCached is set.
+func (fm *FileManager) showGitDiff() {
+	selectedIndex := fm.list.GetCurrentItem()
+	if selectedIndex < 0 || selectedIndex >= len(fm.items) {
+		return
+	}
+	fullPath := filepath.Join(fm.path, fm.items[selectedIndex])
+
+	lines, err := gitinfo.Diff(fullPath, fm.diffCached)
 	if err != nil {
-		fm.details.SetText(fmt.Sprintf("Error deleting file: %v", err))
+		fm.details.SetText(fmt.Sprintf("Error getting diff: %v", err))
+		return
+	}
+
+	var out strings.Builder
+	mode := "working tree"
+	if fm.diffCached {
+		mode = "staged"
+	}
+	fmt.Fprintf(&out, "Diff (%s) for %s:\n", mode, fm.items[selectedIndex])
+	for _, line := range lines {
+		switch line.Kind {
+		case gitinfo.Addition:
+			fmt.Fprintf(&out, "[green]%s[-]\n", tview.Escape(line.Text))
+		case gitinfo.Deletion:
+			fmt.Fprintf(&out, "[red]%s[-]\n", tview.Escape(line.Text))
+		case gitinfo.Hunk:
+			fmt.Fprintf(&out, "[darkcyan]%s[-]\n", tview.Escape(line.Text))
+		default:
+			fmt.Fprintf(&out, "%s\n", tview.Escape(line.Text))
+		}
+	}
+	fm.details.SetText(out.String())
+}
+
+// archiveSelectedItem prompts for an output archive name and packages the
+// selected item into it, gzipping when the name ends in .tar.gz or .tgz.
+func (fm *FileManager) archiveSelectedItem() {
+	selectedIndex := fm.list.GetCurrentItem()
+	if selectedIndex < 0 || selectedIndex >= len(fm.items) {
+		return
+	}
+	source := filepath.Join(fm.path, fm.items[selectedIndex])
+
+	fm.promptSnapshotName("Archive as: ", func(name string) {
+		target := filepath.Join(fm.path, name)
+		if err := archive.Tar(source, target); err != nil {
+			fm.details.SetText(fmt.Sprintf("Error creating archive: %v", err))
+			return
+		}
+		fm.loadItems()
+	})
+}
+
+// extractSelectedItem extracts the selected archive into a sibling
+// directory named after the archive, stripped of its extension.
+func (fm *FileManager) extractSelectedItem() {
+	selectedIndex := fm.list.GetCurrentItem()
+	if selectedIndex < 0 || selectedIndex >= len(fm.items) {
+		return
+	}
+	selectedItem := fm.items[selectedIndex]
+	tarball := filepath.Join(fm.path, selectedItem)
+	if !isArchive(tarball) {
+		fm.details.SetText("Not an archive")
 		return
 	}
 
+	dest := filepath.Join(fm.path, strings.TrimSuffix(strings.TrimSuffix(strings.TrimSuffix(selectedItem, ".tar.gz"), ".tgz"), ".tar"))
+	if err := archive.Untar(tarball, dest); err != nil {
+		fm.details.SetText(fmt.Sprintf("Error extracting archive: %v", err))
+		return
+	}
 	fm.loadItems()
 }
 
+// promptRestoreName asks for a new name when the original path is occupied,
+// then restores the trashed item under that name instead of clobbering it.
+func (fm *FileManager) promptRestoreName(id string) {
+	input := tview.NewInputField().
+		SetLabel("Destination occupied, restore as: ")
+
+	input.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEnter {
+			dest := input.GetText()
+			if err := fm.trash.RestoreAs(id, dest); err != nil {
+				fm.details.SetText(fmt.Sprintf("Error restoring: %v", err))
+			}
+		}
+		fm.pages.RemovePage("restore-prompt")
+		fm.loadItems()
+	})
+
+	fm.pages.AddPage("restore-prompt", input, true, true)
+}
+
 func (fm *FileManager) run() {
 	fm.list.SetSelectedFunc(func(index int, mainText, secondaryText string, shortcut rune) {
 		fm.navigate(mainText)
@@ -207,10 +549,22 @@ func (fm *FileManager) run() {
 			return event
 		}
 
+		// The application-level InputCapture fires before the focused
+		// primitive ever sees the key, so these global shortcuts must only
+		// apply while the main list is on screen. Otherwise they shadow a
+		// modal's or prompt's own key handling (e.g. typing into an
+		// InputField, or a trash-modal's restore/purge keys).
+		if name, _ := fm.pages.GetFrontPage(); name != "main" {
+			return event
+		}
+
 		switch event.Key() {
 		case tcell.KeyBackspace, tcell.KeyBackspace2:
 			fm.path = filepath.Dir(fm.path)
 			fm.loadItems()
+			if err := fm.watcher.Watch(fm.path); err != nil {
+				fm.details.SetText(fmt.Sprintf("Error watching %q: %v", fm.path, err))
+			}
 			return nil
 		case tcell.KeyCtrlD:
 			fm.deleteSelectedItem()
@@ -218,8 +572,34 @@ func (fm *FileManager) run() {
 		case tcell.KeyRune:
 			switch event.Rune() {
 			case 'q':
+				_ = fm.snapshots.Close()
+				_ = fm.watcher.Close()
 				fm.app.Stop()
 				return nil
+			case 'u':
+				fm.undoTrash()
+				return nil
+			case 't':
+				fm.showTrashModal()
+				return nil
+			case 'S':
+				fm.takeSnapshot()
+				return nil
+			case 'D':
+				fm.diffSnapshot()
+				return nil
+			case 'a':
+				fm.archiveSelectedItem()
+				return nil
+			case 'x':
+				fm.extractSelectedItem()
+				return nil
+			case 'd':
+				fm.showGitDiff()
+				return nil
+			case 'c':
+				fm.toggleDiffCached()
+				return nil
 			}
 		}
 		return event
@@ -229,7 +609,8 @@ func (fm *FileManager) run() {
 		AddItem(fm.list, 0, 1, true).
 		AddItem(fm.details, 0, 1, false)
 
-	fm.app.SetRoot(fm.flex, true)
+	fm.pages.AddPage("main", fm.flex, true, true)
+	fm.app.SetRoot(fm.pages, true)
 
 	if err := fm.app.Run(); err != nil {
 		panic(err)
@@ -0,0 +1,57 @@
+package snapshot
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ignoreList holds the gitignore-style globs loaded from a .lfmignore file.
+type ignoreList struct {
+	patterns []string
+}
+
+// loadIgnore reads root/.lfmignore, if present. A missing file yields an
+// empty, always-non-matching ignoreList rather than an error.
+func loadIgnore(root string) (ignoreList, error) {
+	f, err := os.Open(filepath.Join(root, ".lfmignore"))
+	if os.IsNotExist(err) {
+		return ignoreList{}, nil
+	} else if err != nil {
+		return ignoreList{}, err
+	}
+	defer f.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return ignoreList{}, err
+	}
+
+	return ignoreList{patterns: patterns}, nil
+}
+
+// match reports whether the slash-separated relative path matches any
+// configured glob, either directly or against any path component.
+func (l ignoreList) match(relPath string) bool {
+	relPath = filepath.ToSlash(relPath)
+	for _, pattern := range l.patterns {
+		if ok, _ := filepath.Match(pattern, relPath); ok {
+			return true
+		}
+		for _, part := range strings.Split(relPath, "/") {
+			if ok, _ := filepath.Match(pattern, part); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
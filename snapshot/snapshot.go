@@ -0,0 +1,289 @@
+// Package snapshot hashes a directory tree and stores the result in an
+// embedded BoltDB file so later trees can be diffed against it.
+package snapshot
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Entry is the recorded state of a single file at snapshot time.
+type Entry struct {
+	Path   string    `json:"path"`
+	SHA256 string    `json:"sha256"`
+	MTime  time.Time `json:"mtime"`
+	Size   int64     `json:"size"`
+}
+
+// ChangeKind classifies how a path differs between two snapshots.
+type ChangeKind int
+
+const (
+	Added ChangeKind = iota
+	Modified
+	Deleted
+)
+
+// Change describes a single path's difference between a stored snapshot
+// and the current working tree.
+type Change struct {
+	Path string
+	Kind ChangeKind
+}
+
+// Store wraps a BoltDB file holding one bucket per named snapshot.
+type Store struct {
+	db *bolt.DB
+}
+
+// DefaultPath returns ~/.config/linux-filemanager/snapshots.db.
+func DefaultPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("snapshot: resolve home dir: %w", err)
+	}
+	return filepath.Join(home, ".config", "linux-filemanager", "snapshots.db"), nil
+}
+
+// Open opens (creating if necessary) the BoltDB file at path.
+func Open(path string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("snapshot: create db dir: %w", err)
+	}
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("snapshot: open db: %w", err)
+	}
+	return &Store{db: db}, nil
+}
+
+// Close releases the underlying BoltDB file.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Take walks root concurrently, hashes every regular file not excluded by
+// .lfmignore, and stores the result under name, overwriting any snapshot
+// previously stored with that name.
+func (s *Store) Take(name, root string) error {
+	ignore, err := loadIgnore(root)
+	if err != nil {
+		return err
+	}
+
+	paths := make(chan string)
+	results := make(chan Entry)
+	errs := make(chan error, 1)
+
+	var wg sync.WaitGroup
+	workers := runtime.GOMAXPROCS(0)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range paths {
+				entry, err := hashFile(root, path)
+				if err != nil {
+					select {
+					case errs <- err:
+					default:
+					}
+					continue
+				}
+				results <- entry
+			}
+		}()
+	}
+
+	go func() {
+		defer close(paths)
+		walkErr := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.Mode()&os.ModeSymlink != 0 {
+				return nil
+			}
+			if info.IsDir() {
+				return nil
+			}
+			rel, err := filepath.Rel(root, path)
+			if err != nil {
+				return nil
+			}
+			if ignore.match(rel) {
+				return nil
+			}
+			paths <- path
+			return nil
+		})
+		if walkErr != nil {
+			select {
+			case errs <- walkErr:
+			default:
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	var entries []Entry
+	for entry := range results {
+		entries = append(entries, entry)
+	}
+
+	select {
+	case err := <-errs:
+		return fmt.Errorf("snapshot: hash tree: %w", err)
+	default:
+	}
+
+	return s.store(name, entries)
+}
+
+func hashFile(root, path string) (Entry, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return Entry{}, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, bufio.NewReader(f)); err != nil {
+		return Entry{}, err
+	}
+
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	return Entry{
+		Path:   rel,
+		SHA256: hex.EncodeToString(h.Sum(nil)),
+		MTime:  info.ModTime(),
+		Size:   info.Size(),
+	}, nil
+}
+
+// store writes entries into a single bucket named name in one transaction.
+func (s *Store) store(name string, entries []Entry) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.DeleteBucket([]byte(name)); err != nil && err != bolt.ErrBucketNotFound {
+			return err
+		}
+		bucket, err := tx.CreateBucket([]byte(name))
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			data, err := json.Marshal(entry)
+			if err != nil {
+				return err
+			}
+			if err := bucket.Put([]byte(entry.Path), data); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Load reads back every entry stored under name.
+func (s *Store) Load(name string) (map[string]Entry, error) {
+	entries := make(map[string]Entry)
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(name))
+		if bucket == nil {
+			return fmt.Errorf("snapshot: no such snapshot %q", name)
+		}
+		return bucket.ForEach(func(k, v []byte) error {
+			var entry Entry
+			if err := json.Unmarshal(v, &entry); err != nil {
+				return err
+			}
+			entries[entry.Path] = entry
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Diff takes a fresh snapshot of root and compares it against the snapshot
+// stored under name, without persisting the fresh one.
+func (s *Store) Diff(name, root string) ([]Change, error) {
+	stored, err := s.Load(name)
+	if err != nil {
+		return nil, err
+	}
+
+	ignore, err := loadIgnore(root)
+	if err != nil {
+		return nil, err
+	}
+
+	current := make(map[string]Entry)
+	err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode()&os.ModeSymlink != 0 || info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return nil
+		}
+		if ignore.match(rel) {
+			return nil
+		}
+		entry, err := hashFile(root, path)
+		if err != nil {
+			return err
+		}
+		current[rel] = entry
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("snapshot: diff: %w", err)
+	}
+
+	var changes []Change
+	for path, entry := range current {
+		old, ok := stored[path]
+		if !ok {
+			changes = append(changes, Change{Path: path, Kind: Added})
+		} else if old.SHA256 != entry.SHA256 {
+			changes = append(changes, Change{Path: path, Kind: Modified})
+		}
+	}
+	for path := range stored {
+		if _, ok := current[path]; !ok {
+			changes = append(changes, Change{Path: path, Kind: Deleted})
+		}
+	}
+
+	return changes, nil
+}
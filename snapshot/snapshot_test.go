@@ -0,0 +1,107 @@
+package snapshot
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	s, err := Open(filepath.Join(t.TempDir(), "snapshots.db"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestTakeAndDiff(t *testing.T) {
+	s := newTestStore(t)
+	root := t.TempDir()
+	os.WriteFile(filepath.Join(root, "a.txt"), []byte("a"), 0o644)
+	os.WriteFile(filepath.Join(root, "b.txt"), []byte("b"), 0o644)
+
+	if err := s.Take("snap", root); err != nil {
+		t.Fatalf("Take: %v", err)
+	}
+
+	// No changes yet.
+	changes, err := s.Diff("snap", root)
+	if err != nil {
+		t.Fatalf("Diff: %v", err)
+	}
+	if len(changes) != 0 {
+		t.Fatalf("Diff on unchanged tree = %+v, want none", changes)
+	}
+
+	// Modify, add, delete.
+	os.WriteFile(filepath.Join(root, "a.txt"), []byte("a-modified"), 0o644)
+	os.WriteFile(filepath.Join(root, "c.txt"), []byte("c"), 0o644)
+	os.Remove(filepath.Join(root, "b.txt"))
+
+	changes, err = s.Diff("snap", root)
+	if err != nil {
+		t.Fatalf("Diff: %v", err)
+	}
+
+	got := map[string]ChangeKind{}
+	for _, c := range changes {
+		got[c.Path] = c.Kind
+	}
+	want := map[string]ChangeKind{"a.txt": Modified, "c.txt": Added, "b.txt": Deleted}
+	if len(got) != len(want) {
+		t.Fatalf("Diff = %+v, want %+v", got, want)
+	}
+	for path, kind := range want {
+		if got[path] != kind {
+			t.Errorf("Diff[%q] = %v, want %v", path, got[path], kind)
+		}
+	}
+}
+
+func TestTakeHonorsIgnoreList(t *testing.T) {
+	s := newTestStore(t)
+	root := t.TempDir()
+	os.WriteFile(filepath.Join(root, "keep.txt"), []byte("keep"), 0o644)
+	os.WriteFile(filepath.Join(root, "skip.log"), []byte("skip"), 0o644)
+	os.WriteFile(filepath.Join(root, ".lfmignore"), []byte("*.log\n"), 0o644)
+
+	if err := s.Take("snap", root); err != nil {
+		t.Fatalf("Take: %v", err)
+	}
+
+	entries, err := s.Load("snap")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	var names []string
+	for path := range entries {
+		names = append(names, path)
+	}
+	sort.Strings(names)
+
+	if len(names) != 2 || names[0] != ".lfmignore" || names[1] != "keep.txt" {
+		t.Fatalf("Load returned %v, want [.lfmignore keep.txt] (skip.log ignored)", names)
+	}
+}
+
+func TestTakePropagatesWalkErrors(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("running as root: unreadable-directory permissions have no effect")
+	}
+
+	s := newTestStore(t)
+	root := t.TempDir()
+	denied := filepath.Join(root, "denied")
+	if err := os.Mkdir(denied, 0o000); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chmod(denied, 0o755) })
+
+	if err := s.Take("snap", root); err == nil {
+		t.Fatal("expected Take to report the unreadable subdirectory instead of silently dropping it")
+	}
+}
@@ -0,0 +1,326 @@
+// Package trash implements an XDG-compliant trash can: moving files out of
+// harm's way instead of deleting them outright, with undo/restore support.
+package trash
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Item describes a single trashed entry.
+type Item struct {
+	ID           string
+	OriginalPath string
+	TrashedPath  string
+	DeletedAt    time.Time
+}
+
+// Store manages trashed files under a single XDG trash directory
+// (default ~/.local/share/Trash).
+type Store struct {
+	filesDir string
+	infoDir  string
+
+	history []Item // most recent trash operations, for undo
+}
+
+// NewStore creates a Store rooted at the default XDG trash location,
+// creating the files/ and info/ directories if necessary.
+func NewStore() (*Store, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("trash: resolve home dir: %w", err)
+	}
+	root := filepath.Join(home, ".local", "share", "Trash")
+	return newStoreAt(root)
+}
+
+func newStoreAt(root string) (*Store, error) {
+	s := &Store{
+		filesDir: filepath.Join(root, "files"),
+		infoDir:  filepath.Join(root, "info"),
+	}
+	if err := os.MkdirAll(s.filesDir, 0o700); err != nil {
+		return nil, fmt.Errorf("trash: create files dir: %w", err)
+	}
+	if err := os.MkdirAll(s.infoDir, 0o700); err != nil {
+		return nil, fmt.Errorf("trash: create info dir: %w", err)
+	}
+	return s, nil
+}
+
+// Trash moves path into the trash, recording a .trashinfo file alongside it.
+// If path and the trash directory live on different filesystems, it falls
+// back to a recursive copy followed by removal of the source.
+func (s *Store) Trash(path string) (Item, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return Item{}, fmt.Errorf("trash: resolve %q: %w", path, err)
+	}
+
+	id := uniqueName(s.filesDir, filepath.Base(abs))
+	dest := filepath.Join(s.filesDir, id)
+	deletedAt := time.Now()
+
+	if err := os.Rename(abs, dest); err != nil {
+		if !isCrossDevice(err) {
+			return Item{}, fmt.Errorf("trash: move %q: %w", abs, err)
+		}
+		if err := copyTree(abs, dest); err != nil {
+			return Item{}, fmt.Errorf("trash: copy %q: %w", abs, err)
+		}
+		if err := os.RemoveAll(abs); err != nil {
+			return Item{}, fmt.Errorf("trash: remove original %q: %w", abs, err)
+		}
+	}
+
+	if err := writeInfo(s.infoDir, id, abs, deletedAt); err != nil {
+		return Item{}, err
+	}
+
+	item := Item{ID: id, OriginalPath: abs, TrashedPath: dest, DeletedAt: deletedAt}
+	s.history = append(s.history, item)
+	return item, nil
+}
+
+// Undo reverses the most recent Trash operation, restoring it to its
+// original path. It is a no-op returning an error if there is nothing to undo.
+func (s *Store) Undo() (Item, error) {
+	if len(s.history) == 0 {
+		return Item{}, fmt.Errorf("trash: nothing to undo")
+	}
+	last := s.history[len(s.history)-1]
+	if err := s.restore(last, last.OriginalPath); err != nil {
+		return Item{}, err
+	}
+	s.history = s.history[:len(s.history)-1]
+	return last, nil
+}
+
+// Restore moves the item identified by id back to its original location,
+// returning the path it was restored to. If that path is already occupied,
+// the caller should retry with RestoreAs.
+func (s *Store) Restore(id string) (string, error) {
+	item, err := s.lookup(id)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Lstat(item.OriginalPath); err == nil {
+		return "", fmt.Errorf("trash: %q already exists, use RestoreAs", item.OriginalPath)
+	}
+	return item.OriginalPath, s.restore(item, item.OriginalPath)
+}
+
+// RestoreAs restores the item identified by id to an explicit destination,
+// for use when the original path is occupied.
+func (s *Store) RestoreAs(id, dest string) error {
+	item, err := s.lookup(id)
+	if err != nil {
+		return err
+	}
+	return s.restore(item, dest)
+}
+
+func (s *Store) restore(item Item, dest string) error {
+	if err := os.Rename(item.TrashedPath, dest); err != nil {
+		if !isCrossDevice(err) {
+			return fmt.Errorf("trash: restore %q: %w", item.ID, err)
+		}
+		if err := copyTree(item.TrashedPath, dest); err != nil {
+			return fmt.Errorf("trash: restore copy %q: %w", item.ID, err)
+		}
+		if err := os.RemoveAll(item.TrashedPath); err != nil {
+			return fmt.Errorf("trash: clear trashed copy %q: %w", item.ID, err)
+		}
+	}
+	_ = os.Remove(infoPath(s.infoDir, item.ID))
+	return nil
+}
+
+// Purge permanently deletes the trashed item identified by id.
+func (s *Store) Purge(id string) error {
+	item, err := s.lookup(id)
+	if err != nil {
+		return err
+	}
+	if err := os.RemoveAll(item.TrashedPath); err != nil {
+		return fmt.Errorf("trash: purge %q: %w", id, err)
+	}
+	return os.Remove(infoPath(s.infoDir, item.ID))
+}
+
+// Empty permanently deletes every trashed item.
+func (s *Store) Empty() error {
+	items, err := s.List()
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		if err := s.Purge(item.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// List returns all currently trashed items, most recently deleted first.
+func (s *Store) List() ([]Item, error) {
+	entries, err := os.ReadDir(s.infoDir)
+	if err != nil {
+		return nil, fmt.Errorf("trash: list: %w", err)
+	}
+
+	var items []Item
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".trashinfo" {
+			continue
+		}
+		id := strings.TrimSuffix(entry.Name(), ".trashinfo")
+		item, err := s.lookup(id)
+		if err != nil {
+			continue
+		}
+		items = append(items, item)
+	}
+
+	sortItemsByDeletedAtDesc(items)
+	return items, nil
+}
+
+func (s *Store) lookup(id string) (Item, error) {
+	data, err := os.ReadFile(infoPath(s.infoDir, id))
+	if err != nil {
+		return Item{}, fmt.Errorf("trash: unknown item %q: %w", id, err)
+	}
+	origPath, deletedAt, err := parseInfo(data)
+	if err != nil {
+		return Item{}, fmt.Errorf("trash: parse info for %q: %w", id, err)
+	}
+	return Item{
+		ID:           id,
+		OriginalPath: origPath,
+		TrashedPath:  filepath.Join(s.filesDir, id),
+		DeletedAt:    deletedAt,
+	}, nil
+}
+
+func sortItemsByDeletedAtDesc(items []Item) {
+	for i := 1; i < len(items); i++ {
+		for j := i; j > 0 && items[j].DeletedAt.After(items[j-1].DeletedAt); j-- {
+			items[j], items[j-1] = items[j-1], items[j]
+		}
+	}
+}
+
+func infoPath(infoDir, id string) string {
+	return filepath.Join(infoDir, id+".trashinfo")
+}
+
+func writeInfo(infoDir, id, origPath string, deletedAt time.Time) error {
+	contents := fmt.Sprintf(
+		"[Trash Info]\nPath=%s\nDeletionDate=%s\n",
+		(&url.URL{Path: origPath}).EscapedPath(),
+		deletedAt.Format(time.RFC3339),
+	)
+	if err := os.WriteFile(infoPath(infoDir, id), []byte(contents), 0o600); err != nil {
+		return fmt.Errorf("trash: write info for %q: %w", id, err)
+	}
+	return nil
+}
+
+func parseInfo(data []byte) (origPath string, deletedAt time.Time, err error) {
+	for _, line := range strings.Split(string(data), "\n") {
+		switch {
+		case strings.HasPrefix(line, "Path="):
+			origPath, err = url.PathUnescape(strings.TrimPrefix(line, "Path="))
+			if err != nil {
+				return "", time.Time{}, err
+			}
+		case strings.HasPrefix(line, "DeletionDate="):
+			deletedAt, err = time.Parse(time.RFC3339, strings.TrimPrefix(line, "DeletionDate="))
+			if err != nil {
+				return "", time.Time{}, err
+			}
+		}
+	}
+	if origPath == "" {
+		return "", time.Time{}, fmt.Errorf("missing Path= entry")
+	}
+	return origPath, deletedAt, nil
+}
+
+// uniqueName returns a name derived from base guaranteed not to collide with
+// an existing entry under dir, appending "_N" as needed.
+func uniqueName(dir, base string) string {
+	name := base
+	for i := 1; ; i++ {
+		if _, err := os.Lstat(filepath.Join(dir, name)); os.IsNotExist(err) {
+			return name
+		}
+		name = base + "_" + strconv.Itoa(i)
+	}
+}
+
+func isCrossDevice(err error) bool {
+	return errors.Is(err, syscall.EXDEV)
+}
+
+// copyTree recursively copies src to dst, preserving file modes. It mirrors
+// the behavior of packages like otiai10/copy without the extra dependency.
+func copyTree(src, dst string) error {
+	info, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+
+	if info.Mode()&os.ModeSymlink != 0 {
+		target, err := os.Readlink(src)
+		if err != nil {
+			return err
+		}
+		return os.Symlink(target, dst)
+	}
+
+	if info.IsDir() {
+		if err := os.MkdirAll(dst, info.Mode().Perm()); err != nil {
+			return err
+		}
+		entries, err := os.ReadDir(src)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if err := copyTree(filepath.Join(src, entry.Name()), filepath.Join(dst, entry.Name())); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return copyFile(src, dst, info.Mode().Perm())
+}
+
+func copyFile(src, dst string, perm os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, perm)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, in)
+	return err
+}
@@ -0,0 +1,189 @@
+package trash
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	s, err := newStoreAt(t.TempDir())
+	if err != nil {
+		t.Fatalf("newStoreAt: %v", err)
+	}
+	return s
+}
+
+func TestTrashAndRestore(t *testing.T) {
+	s := newTestStore(t)
+	dir := t.TempDir()
+	src := filepath.Join(dir, "file.txt")
+	if err := os.WriteFile(src, []byte("hello"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	item, err := s.Trash(src)
+	if err != nil {
+		t.Fatalf("Trash: %v", err)
+	}
+	if _, err := os.Stat(src); !os.IsNotExist(err) {
+		t.Fatalf("expected source to be gone, got err=%v", err)
+	}
+
+	restored, err := s.Restore(item.ID)
+	if err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if restored != src {
+		t.Fatalf("restored path = %q, want %q", restored, src)
+	}
+
+	data, err := os.ReadFile(src)
+	if err != nil {
+		t.Fatalf("read restored file: %v", err)
+	}
+	if string(data) != "hello" {
+		t.Fatalf("restored contents = %q, want %q", data, "hello")
+	}
+}
+
+func TestRestoreRejectsOccupiedDestination(t *testing.T) {
+	s := newTestStore(t)
+	dir := t.TempDir()
+	src := filepath.Join(dir, "file.txt")
+	os.WriteFile(src, []byte("original"), 0o644)
+
+	item, err := s.Trash(src)
+	if err != nil {
+		t.Fatalf("Trash: %v", err)
+	}
+
+	// Something else now occupies the original path.
+	os.WriteFile(src, []byte("new occupant"), 0o644)
+
+	if _, err := s.Restore(item.ID); err == nil {
+		t.Fatal("expected Restore to reject an occupied destination")
+	}
+
+	dest := filepath.Join(dir, "file-restored.txt")
+	if err := s.RestoreAs(item.ID, dest); err != nil {
+		t.Fatalf("RestoreAs: %v", err)
+	}
+	data, err := os.ReadFile(dest)
+	if err != nil || string(data) != "original" {
+		t.Fatalf("RestoreAs produced %q, %v; want %q", data, err, "original")
+	}
+}
+
+func TestUndo(t *testing.T) {
+	s := newTestStore(t)
+	dir := t.TempDir()
+	src := filepath.Join(dir, "file.txt")
+	os.WriteFile(src, []byte("hello"), 0o644)
+
+	if _, err := s.Trash(src); err != nil {
+		t.Fatalf("Trash: %v", err)
+	}
+
+	item, err := s.Undo()
+	if err != nil {
+		t.Fatalf("Undo: %v", err)
+	}
+	if item.OriginalPath != src {
+		t.Fatalf("Undo restored %q, want %q", item.OriginalPath, src)
+	}
+	if _, err := os.Stat(src); err != nil {
+		t.Fatalf("expected file back at %q: %v", src, err)
+	}
+
+	if _, err := s.Undo(); err == nil {
+		t.Fatal("expected Undo with empty history to fail")
+	}
+}
+
+func TestListAndPurge(t *testing.T) {
+	s := newTestStore(t)
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.txt")
+	b := filepath.Join(dir, "b.txt")
+	os.WriteFile(a, []byte("a"), 0o644)
+	os.WriteFile(b, []byte("b"), 0o644)
+
+	itemA, _ := s.Trash(a)
+	itemB, _ := s.Trash(b)
+
+	items, err := s.List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("List returned %d items, want 2", len(items))
+	}
+
+	if err := s.Purge(itemA.ID); err != nil {
+		t.Fatalf("Purge: %v", err)
+	}
+	items, err = s.List()
+	if err != nil {
+		t.Fatalf("List after purge: %v", err)
+	}
+	if len(items) != 1 || items[0].ID != itemB.ID {
+		t.Fatalf("List after purge = %+v, want only %q", items, itemB.ID)
+	}
+}
+
+func TestTrashinfoPreservesPathSeparators(t *testing.T) {
+	dir := t.TempDir()
+	orig := "/home/user/My Documents/file.txt"
+	if err := writeInfo(dir, "id", orig, time.Now()); err != nil {
+		t.Fatalf("writeInfo: %v", err)
+	}
+
+	data, err := os.ReadFile(infoPath(dir, "id"))
+	if err != nil {
+		t.Fatalf("read info: %v", err)
+	}
+
+	got, _, err := parseInfo(data)
+	if err != nil {
+		t.Fatalf("parseInfo: %v", err)
+	}
+	if got != orig {
+		t.Fatalf("round-tripped path = %q, want %q", got, orig)
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, "Path=") {
+			if strings.Contains(strings.ToUpper(line), "%2F") {
+				t.Fatalf("Path= line escaped '/' as %%2F, want literal separators: %s", line)
+			}
+			if !strings.Contains(line, "/") {
+				t.Fatalf("Path= line lost its separators entirely: %s", line)
+			}
+		}
+	}
+}
+
+func TestCrossDeviceFallbackCopiesTree(t *testing.T) {
+	s := newTestStore(t)
+	dir := t.TempDir()
+	src := filepath.Join(dir, "subdir")
+	if err := os.MkdirAll(src, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "file.txt"), []byte("data"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := copyTree(src, filepath.Join(s.filesDir, "copied")); err != nil {
+		t.Fatalf("copyTree: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(s.filesDir, "copied", "file.txt"))
+	if err != nil || string(data) != "data" {
+		t.Fatalf("copyTree did not reproduce file contents: %v, %q", err, data)
+	}
+}
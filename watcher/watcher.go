@@ -0,0 +1,138 @@
+// Package watcher notifies a callback when a directory's contents change,
+// using Linux inotify with debouncing so bursts of events collapse into a
+// single refresh.
+package watcher
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sync"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+const watchMask = unix.IN_CREATE | unix.IN_DELETE | unix.IN_MOVED_FROM | unix.IN_MOVED_TO | unix.IN_ATTRIB
+
+// debounceInterval bounds how often OnChange fires in response to a burst
+// of inotify events for the same directory.
+const debounceInterval = 100 * time.Millisecond
+
+// Watcher watches a single directory at a time and invokes OnChange,
+// debounced, whenever its contents change.
+type Watcher struct {
+	OnChange func()
+
+	fd       int
+	wd       int
+	mu       sync.Mutex
+	stopCh   chan struct{}
+	debounce *time.Timer
+}
+
+// New creates a Watcher backed by a fresh inotify instance. Call Watch to
+// start watching a directory, and Close to tear it down.
+func New(onChange func()) (*Watcher, error) {
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC)
+	if err != nil {
+		return nil, fmt.Errorf("watcher: inotify_init1: %w", err)
+	}
+
+	w := &Watcher{
+		OnChange: onChange,
+		fd:       fd,
+		wd:       -1,
+		stopCh:   make(chan struct{}),
+	}
+
+	go w.loop()
+	return w, nil
+}
+
+// Watch replaces the currently watched directory with path. It is safe to
+// call repeatedly, e.g. each time the file manager navigates.
+func (w *Watcher) Watch(path string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.wd >= 0 {
+		_, _ = unix.InotifyRmWatch(w.fd, uint32(w.wd))
+		w.wd = -1
+	}
+
+	wd, err := unix.InotifyAddWatch(w.fd, path, watchMask)
+	if err != nil {
+		return fmt.Errorf("watcher: watch %q: %w", path, err)
+	}
+	w.wd = wd
+	return nil
+}
+
+// Close stops the watch loop and releases the inotify file descriptor.
+func (w *Watcher) Close() error {
+	close(w.stopCh)
+
+	// Closing the fd does not wake a read already blocked on it; removing
+	// the active watch queues an IN_IGNORED event, which lets loop return.
+	w.mu.Lock()
+	if w.wd >= 0 {
+		_, _ = unix.InotifyRmWatch(w.fd, uint32(w.wd))
+		w.wd = -1
+	}
+	w.mu.Unlock()
+
+	return unix.Close(w.fd)
+}
+
+const inotifyEventHeaderSize = int(unsafe.Sizeof(unix.InotifyEvent{}))
+
+func (w *Watcher) loop() {
+	buf := make([]byte, 4096)
+	for {
+		n, err := unix.Read(w.fd, buf)
+		select {
+		case <-w.stopCh:
+			return
+		default:
+		}
+		if err != nil || n <= 0 {
+			continue
+		}
+		if w.hasRelevantEvent(buf[:n]) {
+			w.scheduleNotify()
+		}
+	}
+}
+
+// hasRelevantEvent reports whether buf contains at least one event other
+// than IN_IGNORED, which the kernel emits whenever a watch is removed
+// (e.g. because Watch replaced it) and which doesn't reflect a directory
+// content change.
+func (w *Watcher) hasRelevantEvent(buf []byte) bool {
+	for len(buf) >= inotifyEventHeaderSize {
+		mask := binary.LittleEndian.Uint32(buf[4:8])
+		nameLen := binary.LittleEndian.Uint32(buf[12:16])
+		if mask&unix.IN_IGNORED == 0 {
+			return true
+		}
+		buf = buf[inotifyEventHeaderSize+int(nameLen):]
+	}
+	return false
+}
+
+// scheduleNotify coalesces a burst of events into a single OnChange call,
+// fired debounceInterval after the last observed event.
+func (w *Watcher) scheduleNotify() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.debounce != nil {
+		w.debounce.Stop()
+	}
+	w.debounce = time.AfterFunc(debounceInterval, func() {
+		if w.OnChange != nil {
+			w.OnChange()
+		}
+	})
+}
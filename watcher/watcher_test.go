@@ -0,0 +1,115 @@
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatchNotifiesOnCreate(t *testing.T) {
+	dir := t.TempDir()
+
+	notified := make(chan struct{}, 1)
+	w, err := New(func() {
+		select {
+		case notified <- struct{}{}:
+		default:
+		}
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer w.Close()
+
+	if err := w.Watch(dir); err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "new.txt"), []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-notified:
+	case <-time.After(2 * time.Second):
+		t.Fatal("OnChange was not called after a file was created in the watched directory")
+	}
+}
+
+func TestWatchDebouncesBurstsIntoOneNotification(t *testing.T) {
+	dir := t.TempDir()
+
+	var count int
+	notified := make(chan struct{}, 10)
+	w, err := New(func() {
+		count++
+		notified <- struct{}{}
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer w.Close()
+
+	if err := w.Watch(dir); err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+
+	for i := 0; i < 5; i++ {
+		os.WriteFile(filepath.Join(dir, "f"+string(rune('0'+i))+".txt"), []byte("x"), 0o644)
+	}
+
+	select {
+	case <-notified:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected at least one notification for the burst")
+	}
+
+	// Give any further debounce timers a chance to fire before asserting
+	// only one notification made it through the burst.
+	time.Sleep(debounceInterval * 3)
+	if count != 1 {
+		t.Fatalf("got %d notifications for one burst of events, want 1", count)
+	}
+}
+
+func TestWatchReplacesPreviousWatch(t *testing.T) {
+	dirA := t.TempDir()
+	dirB := t.TempDir()
+
+	notified := make(chan struct{}, 10)
+	w, err := New(func() {
+		select {
+		case notified <- struct{}{}:
+		default:
+		}
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer w.Close()
+
+	if err := w.Watch(dirA); err != nil {
+		t.Fatalf("Watch dirA: %v", err)
+	}
+	if err := w.Watch(dirB); err != nil {
+		t.Fatalf("Watch dirB: %v", err)
+	}
+
+	// dirA is no longer watched; this must neither panic nor notify.
+	os.WriteFile(filepath.Join(dirA, "ignored.txt"), []byte("x"), 0o644)
+
+	select {
+	case <-notified:
+		t.Fatal("got a notification for a directory that was replaced by Watch")
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	// dirB is the active watch and must still notify.
+	os.WriteFile(filepath.Join(dirB, "seen.txt"), []byte("x"), 0o644)
+	select {
+	case <-notified:
+	case <-time.After(2 * time.Second):
+		t.Fatal("OnChange was not called for the currently watched directory")
+	}
+}